@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latencytracker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestConcurrentAccess drives UpdateStateWithUnneededList, ObserveDeletion,
+// UpdateThreshold and GetTrackedNodes from many goroutines at once. It is
+// meant to be run with -race to catch unsynchronized access to nodes.
+func TestConcurrentAccess(t *testing.T) {
+	tracker := NewNodeLatencyTracker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	go tracker.Run(ctx, time.Millisecond)
+
+	const nodeCount = 20
+	nodeNames := make([]string, nodeCount)
+	for i := range nodeNames {
+		nodeNames[i] = fmt.Sprintf("node%d", i)
+	}
+
+	for i := 0; i < 50; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			nodes := make([]*apiv1.Node, len(nodeNames))
+			for i, name := range nodeNames {
+				nodes[i] = &apiv1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			}
+			tracker.UpdateStateWithUnneededList(nodes, map[string]bool{}, time.Now(), ReasonEmpty)
+		}()
+		go func(i int) {
+			defer wg.Done()
+			tracker.ObserveDeletion(nodeNames[i%nodeCount], time.Now())
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			tracker.UpdateThreshold(nodeNames[i%nodeCount], time.Duration(i)*time.Millisecond)
+		}(i)
+		go func() {
+			defer wg.Done()
+			_ = tracker.GetTrackedNodes()
+			_ = tracker.GetSlowestUnneededNodes(5)
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latencytracker
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// DebugPath is where DebugHandler should be registered on the autoscaler's
+// debug HTTP mux, e.g. mux.Handle(latencytracker.DebugPath, handler).
+const DebugPath = "/debug/latencytracker"
+
+// defaultDebugTopK is how many of the slowest unneeded nodes DebugHandler
+// reports when no explicit topK is configured.
+const defaultDebugTopK = 20
+
+// DebugHandler serves a JSON snapshot of the slowest currently-unneeded
+// nodes known to a NodeLatencyTracker.
+type DebugHandler struct {
+	tracker *NodeLatencyTracker
+	topK    int
+}
+
+// NewDebugHandler creates a DebugHandler reporting the topK slowest unneeded
+// nodes known to tracker. A topK <= 0 falls back to defaultDebugTopK.
+func NewDebugHandler(tracker *NodeLatencyTracker, topK int) *DebugHandler {
+	if topK <= 0 {
+		topK = defaultDebugTopK
+	}
+	return &DebugHandler{tracker: tracker, topK: topK}
+}
+
+// ServeHTTP writes the current topK slowest-unneeded-nodes snapshot as JSON.
+func (h *DebugHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	samples := h.tracker.GetSlowestUnneededNodes(h.topK)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(samples); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
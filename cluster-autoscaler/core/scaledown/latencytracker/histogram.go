@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latencytracker
+
+import "sort"
+
+// defaultHistogramBuckets are the bucket upper bounds, in seconds, of the
+// tracker's in-process per-reason histogram when a Reason has no explicit
+// override in HistogramConfig. The exported
+// scale_down_unneeded_duration_seconds metric does not use them; it has fixed
+// exponential buckets from 1s to 2048s.
+var defaultHistogramBuckets = []float64{5, 15, 30, 60, 120, 300, 600, 1800, 3600}
+
+// HistogramConfig overrides the default bucket boundaries (in seconds) of the
+// tracker's in-process histogram, keyed per Reason. A Reason absent from the
+// config falls back to defaultHistogramBuckets. It does not change the
+// buckets of the exported scale_down_unneeded_duration_seconds metric.
+type HistogramConfig map[Reason][]float64
+
+// histogram is a minimal non-cumulative histogram: each observation is
+// counted once, in the smallest bucket whose boundary it falls at or below,
+// plus an implicit +Inf bucket for everything above the largest boundary.
+type histogram struct {
+	buckets  []float64
+	counts   []uint64
+	infCount uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	if len(buckets) == 0 {
+		buckets = defaultHistogramBuckets
+	}
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return &histogram{
+		buckets: sorted,
+		counts:  make([]uint64, len(sorted)),
+	}
+}
+
+// observe records a single duration, in seconds, into the histogram.
+func (h *histogram) observe(seconds float64) {
+	for i, bound := range h.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+			return
+		}
+	}
+	h.infCount++
+}
+
+// bucketCounts returns the number of observations recorded in each
+// individual (non-cumulative) bucket, keyed by the bucket's upper bound.
+func (h *histogram) bucketCounts() map[float64]uint64 {
+	out := make(map[float64]uint64, len(h.buckets))
+	for i, bound := range h.buckets {
+		out[bound] = h.counts[i]
+	}
+	return out
+}
+
+// histogramFor returns the histogram used to track durations for reason,
+// lazily creating one from HistogramConfig (falling back to
+// defaultHistogramBuckets) the first time reason is observed.
+func (t *NodeLatencyTracker) histogramFor(reason Reason) *histogram {
+	if t.histograms == nil {
+		t.histograms = make(map[Reason]*histogram)
+	}
+	h, ok := t.histograms[reason]
+	if !ok {
+		h = newHistogram(t.histogramConfig[reason])
+		t.histograms[reason] = h
+	}
+	return h
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latencytracker
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHistogramBucketPlacement(t *testing.T) {
+	h := newHistogram([]float64{10, 30, 60})
+
+	h.observe(5)  // <= 10
+	h.observe(10) // <= 10
+	h.observe(29) // <= 30
+	h.observe(90) // +Inf
+
+	counts := h.bucketCounts()
+	if counts[10] != 2 {
+		t.Errorf("expected 2 observations in the <=10 bucket, got %d", counts[10])
+	}
+	if counts[30] != 1 {
+		t.Errorf("expected 1 observation in the <=30 bucket, got %d", counts[30])
+	}
+	if counts[60] != 0 {
+		t.Errorf("expected 0 observations in the <=60 bucket, got %d", counts[60])
+	}
+	if h.infCount != 1 {
+		t.Errorf("expected 1 observation in the +Inf bucket, got %d", h.infCount)
+	}
+}
+
+func TestTracker_HistogramPerReasonBuckets(t *testing.T) {
+	tracker := NewNodeLatencyTracker(WithHistogramBuckets(HistogramConfig{
+		ReasonExpired: {1, 2},
+	}))
+
+	tracker.observeUnneededDuration(ReasonExpired, 1500*time.Millisecond)
+	tracker.observeUnneededDuration(ReasonEmpty, 1500*time.Millisecond)
+
+	expiredCounts := tracker.histogramFor(ReasonExpired).bucketCounts()
+	if expiredCounts[2] != 1 {
+		t.Errorf("expected ReasonExpired's custom buckets to place 1.5s in the <=2 bucket, got %+v", expiredCounts)
+	}
+
+	emptyCounts := tracker.histogramFor(ReasonEmpty).bucketCounts()
+	if len(emptyCounts) != len(defaultHistogramBuckets) {
+		t.Errorf("expected ReasonEmpty to use the default buckets, got %+v", emptyCounts)
+	}
+}
+
+func TestGetSlowestUnneededNodes(t *testing.T) {
+	tracker := NewNodeLatencyTracker()
+	now := time.Now()
+
+	tracker.UpdateStateWithUnneededList([]*apiv1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "slow"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "fast"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "tie-b"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "tie-a"}},
+	}, map[string]bool{}, now.Add(-time.Hour), ReasonEmpty)
+
+	// Overwrite UnneededSince directly to control the "overdue" ordering
+	// the topK sort depends on.
+	set := func(name string, since time.Time) {
+		info := tracker.nodes[name]
+		info.UnneededSince = since
+		tracker.nodes[name] = info
+	}
+	set("slow", now.Add(-time.Hour))
+	set("fast", now.Add(-time.Minute))
+	set("tie-a", now.Add(-2*time.Minute))
+	set("tie-b", now.Add(-2*time.Minute))
+
+	top := tracker.GetSlowestUnneededNodes(2)
+	if len(top) != 2 || top[0].NodeName != "slow" {
+		t.Fatalf("expected slow first, got %+v", top)
+	}
+
+	top = tracker.GetSlowestUnneededNodes(4)
+	if top[1].NodeName != "tie-a" || top[2].NodeName != "tie-b" {
+		t.Fatalf("expected ties broken by node name (tie-a before tie-b), got %+v", top)
+	}
+
+	tracker.ObserveDeletion("slow", now)
+	top = tracker.GetSlowestUnneededNodes(10)
+	for _, s := range top {
+		if s.NodeName == "slow" {
+			t.Fatalf("expected deleted node %q to leave the topK list, got %+v", "slow", top)
+		}
+	}
+}
+
+func TestDebugHandler(t *testing.T) {
+	tracker := NewNodeLatencyTracker()
+	tracker.UpdateStateWithUnneededList([]*apiv1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node1"}},
+	}, map[string]bool{}, time.Now(), ReasonEmpty)
+
+	handler := NewDebugHandler(tracker, 5)
+	req := httptest.NewRequest("GET", DebugPath, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != 200 {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if rec.Body.Len() == 0 {
+		t.Fatal("expected a non-empty JSON body")
+	}
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latencytracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+// defaultLeaseDuration is how long a ConfigMapStateStore's lease grants
+// exclusive write access before it must be renewed.
+const defaultLeaseDuration = 15 * time.Second
+
+// defaultLeaseRenewInterval is how often Run renews/re-acquires the
+// coordinating Lease when no explicit interval is given.
+const defaultLeaseRenewInterval = 5 * time.Second
+
+// ConfigMapStateStore is a StateStore that persists node state into a
+// namespaced ConfigMap, one key per node, and only writes while it holds a
+// coordination.k8s.io Lease. This mirrors the lease-coordinated leader
+// pattern used by other Kubernetes control-plane components, and ensures
+// standby replicas never race the active leader to write stale state.
+//
+// Leadership is tracked independently of individual writes: Run acquires and
+// periodically renews the Lease in the background, and Save/Delete/SaveBatch
+// simply consult the cached result. This keeps a single reconcile's worth of
+// node updates from turning into a Lease round trip per node.
+type ConfigMapStateStore struct {
+	client         kubernetes.Interface
+	namespace      string
+	configMapName  string
+	leaseName      string
+	holderIdentity string
+	leaseDuration  time.Duration
+
+	leaderMu sync.RWMutex
+	isLeader bool
+}
+
+// NewConfigMapStateStore creates a StateStore backed by a ConfigMap named
+// configMapName in namespace, guarded by a Lease named leaseName. holderIdentity
+// should uniquely identify this autoscaler replica (e.g. its pod name). Run
+// must be started for this store to ever acquire leadership and persist
+// writes; until then, Save/Delete/SaveBatch are no-ops.
+func NewConfigMapStateStore(client kubernetes.Interface, namespace, configMapName, leaseName, holderIdentity string) *ConfigMapStateStore {
+	return &ConfigMapStateStore{
+		client:         client,
+		namespace:      namespace,
+		configMapName:  configMapName,
+		leaseName:      leaseName,
+		holderIdentity: holderIdentity,
+		leaseDuration:  defaultLeaseDuration,
+	}
+}
+
+// Run acquires the coordinating Lease immediately, then renews it on
+// renewInterval (defaultLeaseRenewInterval if <= 0) until ctx is done. This
+// is what makes Save/Delete/SaveBatch cheap: they never talk to the Lease
+// API themselves, only this loop does.
+func (s *ConfigMapStateStore) Run(ctx context.Context, renewInterval time.Duration) {
+	if renewInterval <= 0 {
+		renewInterval = defaultLeaseRenewInterval
+	}
+
+	s.renewLeadership(ctx)
+	ticker := time.NewTicker(renewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.renewLeadership(ctx)
+		}
+	}
+}
+
+func (s *ConfigMapStateStore) renewLeadership(ctx context.Context) {
+	isLeader, err := s.ensureLeader(ctx)
+	if err != nil {
+		klog.Warningf("Failed to renew latency tracker lease %s/%s: %v", s.namespace, s.leaseName, err)
+		isLeader = false
+	}
+	s.leaderMu.Lock()
+	s.isLeader = isLeader
+	s.leaderMu.Unlock()
+}
+
+func (s *ConfigMapStateStore) isCurrentLeader() bool {
+	s.leaderMu.RLock()
+	defer s.leaderMu.RUnlock()
+	return s.isLeader
+}
+
+// Load returns every node state currently persisted in the ConfigMap. It does
+// not require holding the lease, so standby replicas can rehydrate their view
+// on startup ahead of winning leadership.
+func (s *ConfigMapStateStore) Load() (map[string]NodeInfo, error) {
+	ctx := context.Background()
+	cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.configMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return map[string]NodeInfo{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading latency tracker state from configmap %s/%s: %w", s.namespace, s.configMapName, err)
+	}
+
+	out := make(map[string]NodeInfo, len(cm.Data))
+	for nodeName, raw := range cm.Data {
+		var info NodeInfo
+		if err := json.Unmarshal([]byte(raw), &info); err != nil {
+			klog.Warningf("Dropping unreadable latency tracker state for node %q: %v", nodeName, err)
+			continue
+		}
+		out[nodeName] = info
+	}
+	return out, nil
+}
+
+// Save persists the state for a single node. It is a thin wrapper around
+// SaveBatch; prefer SaveBatch directly when writing several nodes at once.
+func (s *ConfigMapStateStore) Save(nodeName string, info NodeInfo) error {
+	return s.SaveBatch(map[string]NodeInfo{nodeName: info}, nil)
+}
+
+// Delete removes the persisted state for a single node. It is a thin wrapper
+// around SaveBatch; prefer SaveBatch directly when deleting several nodes at once.
+func (s *ConfigMapStateStore) Delete(nodeName string) error {
+	return s.SaveBatch(nil, []string{nodeName})
+}
+
+// SaveBatch applies updates and deletes in a single ConfigMap read/modify/write,
+// if and only if this replica currently holds the Lease (as last observed by
+// Run); otherwise it is a silent no-op, since only the active leader persists
+// state.
+func (s *ConfigMapStateStore) SaveBatch(updates map[string]NodeInfo, deletes []string) error {
+	if len(updates) == 0 && len(deletes) == 0 {
+		return nil
+	}
+	if !s.isCurrentLeader() {
+		klog.V(4).Infof("Not the latency tracker lease holder, skipping batched persist of %d update(s)/%d delete(s)", len(updates), len(deletes))
+		return nil
+	}
+
+	marshaled := make(map[string]string, len(updates))
+	for nodeName, info := range updates {
+		raw, err := json.Marshal(info)
+		if err != nil {
+			return fmt.Errorf("marshaling latency tracker state for node %q: %w", nodeName, err)
+		}
+		marshaled[nodeName] = string(raw)
+	}
+
+	return s.updateConfigMap(context.Background(), func(data map[string]string) {
+		for nodeName, raw := range marshaled {
+			data[nodeName] = raw
+		}
+		for _, nodeName := range deletes {
+			delete(data, nodeName)
+		}
+	})
+}
+
+// updateConfigMap applies mutate to the current ConfigMap content and writes
+// the result back, retrying on a resourceVersion conflict (or on losing a race
+// to create the ConfigMap) by re-Getting and re-applying mutate. This matters
+// because SaveBatch is called with t.mu
+// already released (see node_latency_tracker.go), so two goroutines - e.g.
+// the actuator's ObserveDeletion racing the planner's
+// UpdateStateWithUnneededList - can genuinely race a Get/Update pair against
+// the same ConfigMap; without retrying, the loser's update would otherwise be
+// silently dropped on a 409.
+func (s *ConfigMapStateStore) updateConfigMap(ctx context.Context, mutate func(data map[string]string)) error {
+	cms := s.client.CoreV1().ConfigMaps(s.namespace)
+	retriable := func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}
+	return retry.OnError(retry.DefaultRetry, retriable, func() error {
+		cm, err := cms.Get(ctx, s.configMapName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = &apiv1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: s.configMapName, Namespace: s.namespace},
+				Data:       map[string]string{},
+			}
+			mutate(cm.Data)
+			_, err = cms.Create(ctx, cm, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		mutate(cm.Data)
+		_, err = cms.Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// ensureLeader attempts to acquire or renew the coordinating Lease, returning
+// whether this replica currently holds it.
+func (s *ConfigMapStateStore) ensureLeader(ctx context.Context) (bool, error) {
+	leases := s.client.CoordinationV1().Leases(s.namespace)
+	now := metav1.NewMicroTime(time.Now())
+
+	lease, err := leases.Get(ctx, s.leaseName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		durationSeconds := int32(s.leaseDuration.Seconds())
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: s.leaseName, Namespace: s.namespace},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &s.holderIdentity,
+				LeaseDurationSeconds: &durationSeconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		if _, err := leases.Create(ctx, lease, metav1.CreateOptions{}); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	held := lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity == s.holderIdentity
+	expired := lease.Spec.RenewTime == nil ||
+		time.Since(lease.Spec.RenewTime.Time) > time.Duration(*lease.Spec.LeaseDurationSeconds)*time.Second
+	if !held && !expired {
+		return false, nil
+	}
+
+	lease.Spec.HolderIdentity = &s.holderIdentity
+	lease.Spec.RenewTime = &now
+	if _, err := leases.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
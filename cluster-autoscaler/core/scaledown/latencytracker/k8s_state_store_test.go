@@ -0,0 +1,280 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latencytracker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	testNamespace     = "kube-system"
+	testConfigMapName = "cluster-autoscaler-latency-tracker"
+	testLeaseName     = "cluster-autoscaler-latency-tracker"
+)
+
+func newTestConfigMapStateStore(client *fake.Clientset, holderIdentity string) *ConfigMapStateStore {
+	return NewConfigMapStateStore(client, testNamespace, testConfigMapName, testLeaseName, holderIdentity)
+}
+
+func TestConfigMapStateStore_SaveCreatesThenUpdatesConfigMap(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	store := newTestConfigMapStateStore(client, "replica-a")
+	store.renewLeadership(context.Background())
+	info := NodeInfo{UnneededSince: time.Now(), Threshold: time.Second, Reason: ReasonEmpty}
+
+	if err := store.Save("node1", info); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	cm, err := client.CoreV1().ConfigMaps(testNamespace).Get(context.Background(), testConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected Save to create the ConfigMap, got error: %v", err)
+	}
+	if _, ok := cm.Data["node1"]; !ok {
+		t.Fatalf("expected ConfigMap to contain node1, got %+v", cm.Data)
+	}
+
+	if err := store.Save("node2", info); err != nil {
+		t.Fatalf("second Save returned error: %v", err)
+	}
+	cm, err = client.CoreV1().ConfigMaps(testNamespace).Get(context.Background(), testConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if _, ok := cm.Data["node1"]; !ok {
+		t.Fatalf("expected the update path to preserve node1, got %+v", cm.Data)
+	}
+	if _, ok := cm.Data["node2"]; !ok {
+		t.Fatalf("expected the update path to add node2, got %+v", cm.Data)
+	}
+}
+
+func TestConfigMapStateStore_NonLeaderWritesAreNoOp(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	otherHolder := "replica-other"
+	now := metav1.NewMicroTime(time.Now())
+	leaseDurationSeconds := int32(defaultLeaseDuration.Seconds())
+	_, err := client.CoordinationV1().Leases(testNamespace).Create(context.Background(), &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: testLeaseName, Namespace: testNamespace},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &otherHolder,
+			LeaseDurationSeconds: &leaseDurationSeconds,
+			RenewTime:            &now,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed to seed lease: %v", err)
+	}
+
+	store := newTestConfigMapStateStore(client, "replica-a")
+	store.renewLeadership(context.Background())
+	if err := store.Save("node1", NodeInfo{UnneededSince: time.Now()}); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	if err := store.Delete("node1"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+
+	_, err = client.CoreV1().ConfigMaps(testNamespace).Get(context.Background(), testConfigMapName, metav1.GetOptions{})
+	if err == nil {
+		t.Fatal("expected no ConfigMap to be written while another replica holds the lease")
+	}
+}
+
+func TestConfigMapStateStore_TakesOverExpiredLease(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	otherHolder := "replica-other"
+	expiredRenew := metav1.NewMicroTime(time.Now().Add(-time.Hour))
+	leaseDurationSeconds := int32(defaultLeaseDuration.Seconds())
+	_, err := client.CoordinationV1().Leases(testNamespace).Create(context.Background(), &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: testLeaseName, Namespace: testNamespace},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &otherHolder,
+			LeaseDurationSeconds: &leaseDurationSeconds,
+			RenewTime:            &expiredRenew,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed to seed lease: %v", err)
+	}
+
+	store := newTestConfigMapStateStore(client, "replica-a")
+	store.renewLeadership(context.Background())
+	if err := store.Save("node1", NodeInfo{UnneededSince: time.Now()}); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	lease, err := client.CoordinationV1().Leases(testNamespace).Get(context.Background(), testLeaseName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get lease returned error: %v", err)
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != "replica-a" {
+		t.Fatalf("expected replica-a to take over the expired lease, got holder %v", lease.Spec.HolderIdentity)
+	}
+	if _, err := client.CoreV1().ConfigMaps(testNamespace).Get(context.Background(), testConfigMapName, metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected the new leader's write to persist, got error: %v", err)
+	}
+}
+
+func TestConfigMapStateStore_LoadDropsUnparseableEntries(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	info := NodeInfo{UnneededSince: time.Now(), Threshold: time.Second, Reason: ReasonDrifted}
+	store := newTestConfigMapStateStore(client, "replica-a")
+	store.renewLeadership(context.Background())
+	if err := store.Save("good-node", info); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	cm, err := client.CoreV1().ConfigMaps(testNamespace).Get(context.Background(), testConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	cm.Data["bad-node"] = "not-json"
+	if _, err := client.CoreV1().ConfigMaps(testNamespace).Update(context.Background(), cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to seed unparseable entry: %v", err)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if _, ok := loaded["good-node"]; !ok {
+		t.Fatalf("expected good-node to load, got %+v", loaded)
+	}
+	if _, ok := loaded["bad-node"]; ok {
+		t.Fatalf("expected bad-node to be dropped, got %+v", loaded)
+	}
+}
+
+func TestConfigMapStateStore_LoadOnMissingConfigMap(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	store := newTestConfigMapStateStore(client, "replica-a")
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(loaded) != 0 {
+		t.Fatalf("expected no entries for a missing ConfigMap, got %+v", loaded)
+	}
+}
+
+func TestConfigMapStateStore_SaveBatchAppliesUpdatesAndDeletes(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	store := newTestConfigMapStateStore(client, "replica-a")
+	store.renewLeadership(context.Background())
+
+	updates := map[string]NodeInfo{
+		"node1": {UnneededSince: time.Now(), Reason: ReasonEmpty},
+		"node2": {UnneededSince: time.Now(), Reason: ReasonDrifted},
+	}
+	if err := store.SaveBatch(updates, nil); err != nil {
+		t.Fatalf("SaveBatch returned error: %v", err)
+	}
+	if err := store.SaveBatch(map[string]NodeInfo{"node3": {UnneededSince: time.Now()}}, []string{"node1"}); err != nil {
+		t.Fatalf("second SaveBatch returned error: %v", err)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if _, ok := loaded["node1"]; ok {
+		t.Fatalf("expected node1 to have been deleted by the batch, got %+v", loaded)
+	}
+	if _, ok := loaded["node2"]; !ok {
+		t.Fatalf("expected node2 to remain from the first batch, got %+v", loaded)
+	}
+	if _, ok := loaded["node3"]; !ok {
+		t.Fatalf("expected node3 to have been added by the second batch, got %+v", loaded)
+	}
+}
+
+func TestConfigMapStateStore_ConcurrentSaveBatchRetriesOnConflict(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	store := newTestConfigMapStateStore(client, "replica-a")
+	store.renewLeadership(context.Background())
+
+	const numGoroutines = 10
+	var wg sync.WaitGroup
+	errs := make([]error, numGoroutines)
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nodeName := fmt.Sprintf("node%d", i)
+			errs[i] = store.SaveBatch(map[string]NodeInfo{nodeName: {UnneededSince: time.Now(), Reason: ReasonEmpty}}, nil)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("SaveBatch %d returned error: %v", i, err)
+		}
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(loaded) != numGoroutines {
+		t.Fatalf("expected all %d concurrent writes to be retained, got %+v", numGoroutines, loaded)
+	}
+	for i := 0; i < numGoroutines; i++ {
+		nodeName := fmt.Sprintf("node%d", i)
+		if _, ok := loaded[nodeName]; !ok {
+			t.Errorf("expected %s to have been persisted, got %+v", nodeName, loaded)
+		}
+	}
+}
+
+func TestConfigMapStateStore_RunAcquiresLeaseAndStopsOnCancel(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	store := newTestConfigMapStateStore(client, "replica-a")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		store.Run(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.After(time.Second)
+	for !store.isCurrentLeader() {
+		select {
+		case <-deadline:
+			cancel()
+			t.Fatal("expected Run to acquire leadership")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected Run to return after context cancellation")
+	}
+}
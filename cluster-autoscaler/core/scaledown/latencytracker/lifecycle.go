@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latencytracker
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	"k8s.io/klog/v2"
+)
+
+// stuckThresholdMultiplier is how far past its recorded threshold a node can
+// remain unneeded before Run considers it stuck.
+const stuckThresholdMultiplier = 2
+
+// Run periodically scans tracked nodes for ones that have been unneeded for
+// more than stuckThresholdMultiplier times their recorded threshold without
+// progressing to deletion, reporting them via a warning log and the
+// scale_down_stuck_unneeded_total counter. Run blocks until ctx is done.
+func (t *NodeLatencyTracker) Run(ctx context.Context, scanInterval time.Duration) {
+	ticker := time.NewTicker(scanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			t.reportStuckNodes(now)
+		}
+	}
+}
+
+func (t *NodeLatencyTracker) reportStuckNodes(now time.Time) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for name, info := range t.nodes {
+		if info.Threshold <= 0 {
+			continue
+		}
+		stuckThreshold := info.Threshold * stuckThresholdMultiplier
+		unneededFor := now.Sub(info.UnneededSince)
+		if unneededFor <= stuckThreshold {
+			continue
+		}
+		metrics.RegisterScaleDownStuckUnneeded(info.Reason.String())
+		klog.Warningf(
+			"Node %q has been unneeded for %s, more than %dx its threshold %s (reason: %s) - it may be stuck",
+			name, unneededFor, stuckThresholdMultiplier, info.Threshold, info.Reason,
+		)
+	}
+}
+
+// Close flushes any AsyncObserver queued via AddObserver, waiting for their
+// already-queued events to be delivered before returning.
+func (t *NodeLatencyTracker) Close() {
+	t.mu.RLock()
+	observers := append([]Observer(nil), t.observers...)
+	t.mu.RUnlock()
+
+	for _, o := range observers {
+		if async, ok := o.(*AsyncObserver); ok {
+			async.Close()
+		}
+	}
+}
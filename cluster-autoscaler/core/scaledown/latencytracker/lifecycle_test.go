@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latencytracker
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReportStuckNodes(t *testing.T) {
+	tracker := NewNodeLatencyTracker()
+	now := time.Now()
+
+	tracker.nodes["stuck"] = NodeInfo{UnneededSince: now.Add(-time.Hour), Threshold: time.Minute, Reason: ReasonEmpty}
+	tracker.nodes["fine"] = NodeInfo{UnneededSince: now.Add(-time.Minute), Threshold: time.Hour, Reason: ReasonEmpty}
+	tracker.nodes["no-threshold"] = NodeInfo{UnneededSince: now.Add(-time.Hour), Threshold: 0, Reason: ReasonEmpty}
+
+	// reportStuckNodes only logs/counts; this exercises the scan for races
+	// and panics rather than asserting on metrics/log output.
+	tracker.reportStuckNodes(now)
+}
+
+func TestRunStopsOnContextCancel(t *testing.T) {
+	tracker := NewNodeLatencyTracker()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		tracker.Run(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected Run to return after context cancellation")
+	}
+}
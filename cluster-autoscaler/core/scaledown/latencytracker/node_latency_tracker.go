@@ -17,6 +17,9 @@ limitations under the License.
 package latencytracker
 
 import (
+	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	apiv1 "k8s.io/api/core/v1"
@@ -24,94 +27,343 @@ import (
 	"k8s.io/klog/v2"
 )
 
+// Reason identifies why a node was classified as unneeded. It is captured
+// the moment a node is first observed as unneeded and is attached to the
+// resulting scale-down latency metric so per-reason durations can be
+// distinguished, mirroring the disruption-reason taxonomies used elsewhere
+// in Kubernetes to explain why a pod or node left the cluster.
+type Reason string
+
+const (
+	// ReasonUnknown is used when no classification was supplied.
+	ReasonUnknown Reason = "Unknown"
+	// ReasonEmpty means the node had no non-mirrored, non-DaemonSet pods.
+	ReasonEmpty Reason = "Empty"
+	// ReasonUnderutilized means the node's utilization was below the scale-down threshold.
+	ReasonUnderutilized Reason = "Underutilized"
+	// ReasonDrifted means the node was marked unneeded because its node group drifted
+	// from its desired configuration.
+	ReasonDrifted Reason = "Drifted"
+	// ReasonExpired means the node exceeded its maximum node lifetime.
+	ReasonExpired Reason = "Expired"
+	// ReasonTaintEvicted means the node was marked unneeded as a result of a
+	// scale-down taint placed on it.
+	ReasonTaintEvicted Reason = "TaintEvicted"
+)
+
+// String returns the Reason as a plain string, suitable for use as a metric label.
+func (r Reason) String() string {
+	if r == "" {
+		return string(ReasonUnknown)
+	}
+	return string(r)
+}
+
+// reasonRank orders reasons so a later call can upgrade a node's recorded
+// reason but never downgrade it. Drift and expiry are treated as stronger
+// signals than empty/underutilized/taint-evicted, since they are
+// unconditional decisions to remove the node rather than utilization-based
+// heuristics that could flip back and forth between reconciles.
+var reasonRank = map[Reason]int{
+	ReasonUnknown:       0,
+	ReasonEmpty:         1,
+	ReasonUnderutilized: 1,
+	ReasonTaintEvicted:  1,
+	ReasonDrifted:       2,
+	ReasonExpired:       2,
+}
+
+func (r Reason) overrides(existing Reason) bool {
+	return reasonRank[r] > reasonRank[existing]
+}
+
 // LatencyTracker defines the interface for tracking node removal latency.
 // Implementations record when nodes become unneeded, observe deletion events,
 // and expose thresholds for measuring node removal duration.
 type LatencyTracker interface {
 	ObserveDeletion(nodeName string, timestamp time.Time)
-	UpdateStateWithUnneededList(list []*apiv1.Node, currentlyInDeletion map[string]bool, timestamp time.Time)
+	UpdateStateWithUnneededList(list []*apiv1.Node, currentlyInDeletion map[string]bool, timestamp time.Time, reason Reason)
 	UpdateThreshold(nodeName string, threshold time.Duration)
 	GetTrackedNodes() []string
+	AddObserver(observer Observer)
 }
-type nodeInfo struct {
-	unneededSince time.Time
-	threshold     time.Duration
+
+// NodeInfo holds everything the tracker knows about a node it is currently
+// following as unneeded.
+type NodeInfo struct {
+	UnneededSince time.Time
+	Threshold     time.Duration
+	Reason        Reason
 }
 
 // NodeLatencyTracker is a concrete implementation of LatencyTracker.
 // It keeps track of nodes that are marked as unneeded, when they became unneeded,
 // and thresholds to adjust node removal latency metrics.
 type NodeLatencyTracker struct {
-	nodes map[string]nodeInfo
+	mu              sync.RWMutex
+	nodes           map[string]NodeInfo
+	store           StateStore
+	histograms      map[Reason]*histogram
+	histogramConfig HistogramConfig
+	observers       []Observer
+}
+
+// Option configures optional behavior of a NodeLatencyTracker.
+type Option func(*NodeLatencyTracker)
+
+// WithHistogramBuckets overrides the default bucket boundaries (in seconds)
+// of the tracker's in-process histogram, optionally per Reason. A Reason
+// absent from config keeps using defaultHistogramBuckets. The exported
+// scale_down_unneeded_duration_seconds metric keeps its fixed buckets.
+func WithHistogramBuckets(config HistogramConfig) Option {
+	return func(t *NodeLatencyTracker) {
+		t.histogramConfig = config
+	}
 }
 
-// NewNodeLatencyTracker creates a new tracker.
-func NewNodeLatencyTracker() *NodeLatencyTracker {
-	return &NodeLatencyTracker{
-		nodes: make(map[string]nodeInfo),
+// NewNodeLatencyTracker creates a new tracker backed by an in-memory
+// StateStore, preserving the tracker's historical behavior of not surviving
+// autoscaler restarts.
+func NewNodeLatencyTracker(opts ...Option) *NodeLatencyTracker {
+	tracker, err := NewNodeLatencyTrackerFromStore(NewInMemoryStateStore(), opts...)
+	if err != nil {
+		// NewInMemoryStateStore.Load never fails.
+		klog.Fatalf("unexpected error creating in-memory latency tracker: %v", err)
 	}
+	return tracker
+}
+
+// NewNodeLatencyTrackerFromStore creates a tracker that rehydrates its
+// in-memory view from store on startup, and write-through's subsequent
+// updates back to it. This is what makes the reported scale-down latency
+// survive an autoscaler restart or a leader-election handover.
+func NewNodeLatencyTrackerFromStore(store StateStore, opts ...Option) (*NodeLatencyTracker, error) {
+	nodes, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading latency tracker state: %w", err)
+	}
+	if nodes == nil {
+		nodes = make(map[string]NodeInfo)
+	}
+	t := &NodeLatencyTracker{
+		nodes:      nodes,
+		store:      store,
+		histograms: make(map[Reason]*histogram),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t, nil
 }
 
 // UpdateStateWithUnneededList records unneeded nodes and handles missing ones.
+// reason classifies why the nodes in list were found unneeded (e.g. the
+// empty-nodes path vs. the underutilized path); the tracker keeps the
+// earliest-assigned reason for a node unless a stronger reason (drift or
+// expiry) supersedes it.
 func (t *NodeLatencyTracker) UpdateStateWithUnneededList(
 	list []*apiv1.Node,
 	currentlyInDeletion map[string]bool,
 	timestamp time.Time,
+	reason Reason,
 ) {
+	t.mu.Lock()
+
+	toSave := make(map[string]NodeInfo)
+	toForget := make([]string, 0)
+	var pendingNotifications []func(observers []Observer)
+
 	currentSet := make(map[string]struct{}, len(list))
 	for _, node := range list {
 		currentSet[node.Name] = struct{}{}
 
-		if _, exists := t.nodes[node.Name]; !exists {
-			t.nodes[node.Name] = nodeInfo{
-				unneededSince: timestamp,
-				threshold:     0,
+		if info, exists := t.nodes[node.Name]; !exists {
+			info = NodeInfo{
+				UnneededSince: timestamp,
+				Threshold:     0,
+				Reason:        reason,
 			}
-			klog.V(4).Infof("Started tracking unneeded node %s at %v", node.Name, timestamp)
+			t.nodes[node.Name] = info
+			toSave[node.Name] = info
+			nodeName := node.Name
+			pendingNotifications = append(pendingNotifications, func(observers []Observer) {
+				notifyUnneeded(observers, nodeName, timestamp, reason)
+			})
+			klog.V(4).Infof("Started tracking unneeded node %s at %v (reason: %s)", node.Name, timestamp, reason)
+		} else if reason.overrides(info.Reason) {
+			info.Reason = reason
+			t.nodes[node.Name] = info
+			toSave[node.Name] = info
+			klog.V(4).Infof("Upgraded unneeded reason for node %s to %s", node.Name, reason)
 		}
 	}
 
 	for name, info := range t.nodes {
 		if _, stillUnneeded := currentSet[name]; !stillUnneeded {
 			if _, inDeletion := currentlyInDeletion[name]; !inDeletion {
-				duration := timestamp.Sub(info.unneededSince)
-				metrics.UpdateScaleDownNodeRemovalLatency(false, duration-info.threshold)
+				duration := timestamp.Sub(info.UnneededSince)
+				metrics.UpdateScaleDownNodeRemovalLatency(info.Reason.String(), false, duration-info.Threshold)
+				t.observeUnneededDuration(info.Reason, duration-info.Threshold)
+				nodeName := name
+				pendingNotifications = append(pendingNotifications, func(observers []Observer) {
+					notifyUnneededCleared(observers, nodeName, duration)
+				})
 				delete(t.nodes, name)
-				klog.V(4).Infof("Node %q reported as deleted/missing (unneeded for %s, threshold %s)",
-					name, duration, info.threshold)
+				delete(toSave, name)
+				toForget = append(toForget, name)
+				klog.V(4).Infof("Node %q reported as deleted/missing (unneeded for %s, threshold %s, reason %s)",
+					name, duration, info.Threshold, info.Reason)
 			}
 		}
 	}
+
+	observers := append([]Observer(nil), t.observers...)
+	t.mu.Unlock()
+
+	t.persistBatch(toSave, toForget)
+	for _, deliver := range pendingNotifications {
+		deliver(observers)
+	}
+}
+
+// persist write-throughs a node's state to the backing StateStore, logging
+// (rather than failing the caller) on error: the in-memory view, which
+// drives actual scale-down decisions, is always kept authoritative.
+func (t *NodeLatencyTracker) persist(nodeName string, info NodeInfo) {
+	if t.store == nil {
+		return
+	}
+	if err := t.store.Save(nodeName, info); err != nil {
+		klog.Warningf("Failed to persist latency tracker state for node %q: %v", nodeName, err)
+	}
+}
+
+// forget write-throughs the removal of a node's state to the backing StateStore.
+func (t *NodeLatencyTracker) forget(nodeName string) {
+	if t.store == nil {
+		return
+	}
+	if err := t.store.Delete(nodeName); err != nil {
+		klog.Warningf("Failed to delete persisted latency tracker state for node %q: %v", nodeName, err)
+	}
+}
+
+// persistBatch write-throughs a whole reconcile's worth of node updates and
+// removals to the backing StateStore in a single call, instead of one
+// Save/Delete round trip per node. This keeps UpdateStateWithUnneededList
+// from serializing dozens of blocking API-server calls behind t.mu when it
+// processes a large unneeded list.
+func (t *NodeLatencyTracker) persistBatch(updates map[string]NodeInfo, deletes []string) {
+	if t.store == nil {
+		return
+	}
+	if len(updates) == 0 && len(deletes) == 0 {
+		return
+	}
+	if err := t.store.SaveBatch(updates, deletes); err != nil {
+		klog.Warningf("Failed to persist batched latency tracker state (%d update(s), %d delete(s)): %v", len(updates), len(deletes), err)
+	}
 }
 
 // ObserveDeletion is called by the actuator just before node deletion.
 func (t *NodeLatencyTracker) ObserveDeletion(nodeName string, timestamp time.Time) {
-	if info, exists := t.nodes[nodeName]; exists {
-		duration := timestamp.Sub(info.unneededSince)
+	t.mu.Lock()
+
+	info, exists := t.nodes[nodeName]
+	if !exists {
+		t.mu.Unlock()
+		return
+	}
+
+	duration := timestamp.Sub(info.UnneededSince)
+
+	klog.V(4).Infof(
+		"Observing deletion for node %s, unneeded for %s (threshold was %s, reason %s).",
+		nodeName, duration, info.Threshold, info.Reason,
+	)
 
-		klog.V(4).Infof(
-			"Observing deletion for node %s, unneeded for %s (threshold was %s).",
-			nodeName, duration, info.threshold,
-		)
+	metrics.UpdateScaleDownNodeRemovalLatency(info.Reason.String(), true, duration-info.Threshold)
+	t.observeUnneededDuration(info.Reason, duration-info.Threshold)
+	delete(t.nodes, nodeName)
+	observers := append([]Observer(nil), t.observers...)
+	t.mu.Unlock()
 
-		metrics.UpdateScaleDownNodeRemovalLatency(true, duration-info.threshold)
-		delete(t.nodes, nodeName)
+	t.forget(nodeName)
+	notifyDeletionObserved(observers, nodeName, duration, info.Threshold)
+}
+
+// observeUnneededDuration records duration, the time a node spent unneeded
+// past its threshold, into the per-reason histogram and forwards it to the
+// metrics package as the scale_down_unneeded_duration_seconds histogram.
+func (t *NodeLatencyTracker) observeUnneededDuration(reason Reason, duration time.Duration) {
+	seconds := duration.Seconds()
+	if seconds < 0 {
+		seconds = 0
+	}
+	t.histogramFor(reason).observe(seconds)
+	metrics.UpdateScaleDownUnneededDuration(reason.String(), duration)
+}
+
+// NodeLatencySample is a point-in-time snapshot of how overdue a node is for
+// scale-down.
+type NodeLatencySample struct {
+	NodeName string
+	Reason   Reason
+	Overdue  time.Duration
+}
+
+// GetSlowestUnneededNodes returns up to k currently-tracked nodes, ordered by
+// how long they have been unneeded past their threshold (longest first).
+// Ties are broken by node name so the result is stable across calls.
+func (t *NodeLatencyTracker) GetSlowestUnneededNodes(k int) []NodeLatencySample {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	now := time.Now()
+	samples := make([]NodeLatencySample, 0, len(t.nodes))
+	for name, info := range t.nodes {
+		samples = append(samples, NodeLatencySample{
+			NodeName: name,
+			Reason:   info.Reason,
+			Overdue:  now.Sub(info.UnneededSince) - info.Threshold,
+		})
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		if samples[i].Overdue != samples[j].Overdue {
+			return samples[i].Overdue > samples[j].Overdue
+		}
+		return samples[i].NodeName < samples[j].NodeName
+	})
+	if k < len(samples) {
+		samples = samples[:k]
 	}
+	return samples
 }
 
 // UpdateThreshold updates the scale-down threshold for a tracked node.
 func (t *NodeLatencyTracker) UpdateThreshold(nodeName string, threshold time.Duration) {
-	if info, exists := t.nodes[nodeName]; exists {
-		info.threshold = threshold
-		t.nodes[nodeName] = info
-		klog.V(4).Infof("Updated threshold for node %q to %s", nodeName, threshold)
-	} else {
+	t.mu.Lock()
+
+	info, exists := t.nodes[nodeName]
+	if !exists {
+		t.mu.Unlock()
 		klog.Warningf("Attempted to update threshold for unknown node %q", nodeName)
+		return
 	}
+	info.Threshold = threshold
+	t.nodes[nodeName] = info
+	t.mu.Unlock()
+
+	t.persist(nodeName, info)
+	klog.V(4).Infof("Updated threshold for node %q to %s", nodeName, threshold)
 }
 
-// GetTrackedNodes returns the names of all nodes currently tracked as unneeded.
+// GetTrackedNodes returns a snapshot of the names of all nodes currently
+// tracked as unneeded.
 func (t *NodeLatencyTracker) GetTrackedNodes() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
 	names := make([]string, 0, len(t.nodes))
 	for name := range t.nodes {
 		names = append(names, name)
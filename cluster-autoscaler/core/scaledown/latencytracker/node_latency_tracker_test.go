@@ -31,6 +31,7 @@ func TestNodeLatencyTracker(t *testing.T) {
 		name                  string
 		setupNodes            map[string]NodeInfo
 		unneededList          []string
+		unneededReason        Reason
 		currentlyInDeletion   map[string]bool
 		updateThresholds      map[string]time.Duration
 		observeDeletion       []string
@@ -41,6 +42,7 @@ func TestNodeLatencyTracker(t *testing.T) {
 			name:                 "add new unneeded nodes",
 			setupNodes:           map[string]NodeInfo{},
 			unneededList:         []string{"node1", "node2"},
+			unneededReason:       ReasonUnderutilized,
 			currentlyInDeletion:  map[string]bool{},
 			updateThresholds:     map[string]time.Duration{},
 			observeDeletion:      []string{},
@@ -49,9 +51,10 @@ func TestNodeLatencyTracker(t *testing.T) {
 		{
 			name: "observe deletion with threshold",
 			setupNodes: map[string]NodeInfo{
-				"node1": {UnneededSince: baseTime, Threshold: 2 * time.Second},
+				"node1": {UnneededSince: baseTime, Threshold: 2 * time.Second, Reason: ReasonEmpty},
 			},
 			unneededList:         []string{},
+			unneededReason:       ReasonEmpty,
 			currentlyInDeletion:  map[string]bool{},
 			updateThresholds:     map[string]time.Duration{},
 			observeDeletion:      []string{"node1"},
@@ -63,10 +66,11 @@ func TestNodeLatencyTracker(t *testing.T) {
 		{
 			name: "remove unneeded node not in deletion",
 			setupNodes: map[string]NodeInfo{
-				"node1": {UnneededSince: baseTime, Threshold: 1 * time.Second},
-				"node2": {UnneededSince: baseTime, Threshold: 0},
+				"node1": {UnneededSince: baseTime, Threshold: 1 * time.Second, Reason: ReasonEmpty},
+				"node2": {UnneededSince: baseTime, Threshold: 0, Reason: ReasonUnderutilized},
 			},
 			unneededList:         []string{"node2"}, // node1 is removed from unneeded
+			unneededReason:       ReasonUnderutilized,
 			currentlyInDeletion:  map[string]bool{},
 			updateThresholds:     map[string]time.Duration{},
 			observeDeletion:      []string{},
@@ -78,9 +82,10 @@ func TestNodeLatencyTracker(t *testing.T) {
 		{
 			name: "update threshold",
 			setupNodes: map[string]NodeInfo{
-				"node1": {UnneededSince: baseTime, Threshold: 1 * time.Second},
+				"node1": {UnneededSince: baseTime, Threshold: 1 * time.Second, Reason: ReasonEmpty},
 			},
 			unneededList:        []string{"node1"},
+			unneededReason:      ReasonEmpty,
 			currentlyInDeletion: map[string]bool{},
 			updateThresholds: map[string]time.Duration{
 				"node1": 4 * time.Second,
@@ -106,7 +111,7 @@ func TestNodeLatencyTracker(t *testing.T) {
 			}
 			// simulate current timestamp as baseTime + 5s
 			currentTime := baseTime.Add(5 * time.Second)
-			tracker.UpdateStateWithUnneededList(unneededNodes, tt.currentlyInDeletion, currentTime)
+			tracker.UpdateStateWithUnneededList(unneededNodes, tt.currentlyInDeletion, currentTime, tt.unneededReason)
 
 			// Observe deletions
 			for _, node := range tt.observeDeletion {
@@ -142,3 +147,50 @@ func TestNodeLatencyTracker(t *testing.T) {
 		})
 	}
 }
+
+func TestNodeLatencyTrackerReasons(t *testing.T) {
+	baseTime := time.Now()
+
+	t.Run("different reasons are recorded per node", func(t *testing.T) {
+		tracker := NewNodeLatencyTracker()
+		emptyNode := []*apiv1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "empty-node"}}}
+		underutilizedNode := []*apiv1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "underutilized-node"}}}
+
+		tracker.UpdateStateWithUnneededList(emptyNode, map[string]bool{}, baseTime, ReasonEmpty)
+		tracker.UpdateStateWithUnneededList(underutilizedNode, map[string]bool{}, baseTime, ReasonUnderutilized)
+
+		if got := tracker.nodes["empty-node"].Reason; got != ReasonEmpty {
+			t.Errorf("expected empty-node reason %q, got %q", ReasonEmpty, got)
+		}
+		if got := tracker.nodes["underutilized-node"].Reason; got != ReasonUnderutilized {
+			t.Errorf("expected underutilized-node reason %q, got %q", ReasonUnderutilized, got)
+		}
+	})
+
+	t.Run("reason is stable across threshold updates", func(t *testing.T) {
+		tracker := NewNodeLatencyTracker()
+		node := []*apiv1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}}
+		tracker.UpdateStateWithUnneededList(node, map[string]bool{}, baseTime, ReasonExpired)
+		tracker.UpdateThreshold("node1", 10*time.Second)
+
+		if got := tracker.nodes["node1"].Reason; got != ReasonExpired {
+			t.Errorf("expected reason to remain %q after threshold update, got %q", ReasonExpired, got)
+		}
+	})
+
+	t.Run("drift and expiry upgrade a weaker reason but are never downgraded", func(t *testing.T) {
+		tracker := NewNodeLatencyTracker()
+		node := []*apiv1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}}
+
+		tracker.UpdateStateWithUnneededList(node, map[string]bool{}, baseTime, ReasonEmpty)
+		tracker.UpdateStateWithUnneededList(node, map[string]bool{}, baseTime, ReasonDrifted)
+		if got := tracker.nodes["node1"].Reason; got != ReasonDrifted {
+			t.Errorf("expected reason to upgrade to %q, got %q", ReasonDrifted, got)
+		}
+
+		tracker.UpdateStateWithUnneededList(node, map[string]bool{}, baseTime, ReasonUnderutilized)
+		if got := tracker.nodes["node1"].Reason; got != ReasonDrifted {
+			t.Errorf("expected reason to stay %q, got %q", ReasonDrifted, got)
+		}
+	})
+}
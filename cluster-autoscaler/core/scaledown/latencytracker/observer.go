@@ -0,0 +1,172 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latencytracker
+
+import (
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+// Observer reacts to scale-down lifecycle transitions a NodeLatencyTracker
+// observes, without needing to modify the tracker itself. Implementations
+// must not block: a slow or stuck Observer would otherwise stall the
+// planner/actuator call path that drives the tracker. Wrap a slow Observer
+// in an AsyncObserver to decouple it.
+type Observer interface {
+	// OnUnneeded is called the first time a node is marked unneeded.
+	OnUnneeded(nodeName string, since time.Time, reason Reason)
+	// OnUnneededCleared is called when a node stops being unneeded without
+	// being deleted (e.g. it became needed again before scale-down fired).
+	OnUnneededCleared(nodeName string, duration time.Duration)
+	// OnDeletionObserved is called just before a node is deleted.
+	OnDeletionObserved(nodeName string, duration time.Duration, threshold time.Duration)
+}
+
+// AddObserver registers observer to be notified of subsequent unneeded and
+// deletion events.
+func (t *NodeLatencyTracker) AddObserver(observer Observer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.observers = append(t.observers, observer)
+}
+
+// notifyUnneeded, notifyUnneededCleared and notifyDeletionObserved take an
+// explicit observer snapshot rather than reading t.observers directly, so
+// callers can release t.mu before fanning out. An Observer that calls back
+// into the tracker (e.g. GetTrackedNodes) would otherwise deadlock on the
+// non-reentrant lock.
+func notifyUnneeded(observers []Observer, nodeName string, since time.Time, reason Reason) {
+	for _, o := range observers {
+		o.OnUnneeded(nodeName, since, reason)
+	}
+}
+
+func notifyUnneededCleared(observers []Observer, nodeName string, duration time.Duration) {
+	for _, o := range observers {
+		o.OnUnneededCleared(nodeName, duration)
+	}
+}
+
+func notifyDeletionObserved(observers []Observer, nodeName string, duration, threshold time.Duration) {
+	for _, o := range observers {
+		o.OnDeletionObserved(nodeName, duration, threshold)
+	}
+}
+
+// observerEvent is a single queued notification for AsyncObserver.
+type observerEvent struct {
+	deliver func(Observer)
+}
+
+// AsyncObserver adapts an Observer so it is notified from a background
+// goroutine over a buffered channel, rather than synchronously on the
+// tracker's call path. Events are dropped (with a warning log) if the
+// wrapped Observer falls behind and the buffer fills up, so a stuck
+// downstream consumer can never block scale-down.
+type AsyncObserver struct {
+	next   Observer
+	events chan observerEvent
+	done   chan struct{}
+}
+
+// NewAsyncObserver starts a goroutine that delivers events to next in order,
+// buffering up to bufferSize pending events.
+func NewAsyncObserver(next Observer, bufferSize int) *AsyncObserver {
+	a := &AsyncObserver{
+		next:   next,
+		events: make(chan observerEvent, bufferSize),
+		done:   make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *AsyncObserver) run() {
+	defer close(a.done)
+	for ev := range a.events {
+		ev.deliver(a.next)
+	}
+}
+
+func (a *AsyncObserver) enqueue(deliver func(Observer)) {
+	select {
+	case a.events <- observerEvent{deliver: deliver}:
+	default:
+		klog.Warningf("AsyncObserver buffer full, dropping event")
+	}
+}
+
+// OnUnneeded implements Observer.
+func (a *AsyncObserver) OnUnneeded(nodeName string, since time.Time, reason Reason) {
+	a.enqueue(func(o Observer) { o.OnUnneeded(nodeName, since, reason) })
+}
+
+// OnUnneededCleared implements Observer.
+func (a *AsyncObserver) OnUnneededCleared(nodeName string, duration time.Duration) {
+	a.enqueue(func(o Observer) { o.OnUnneededCleared(nodeName, duration) })
+}
+
+// OnDeletionObserved implements Observer.
+func (a *AsyncObserver) OnDeletionObserved(nodeName string, duration, threshold time.Duration) {
+	a.enqueue(func(o Observer) { o.OnDeletionObserved(nodeName, duration, threshold) })
+}
+
+// Close stops accepting new events and waits for already-queued events to be
+// delivered to the wrapped Observer.
+func (a *AsyncObserver) Close() {
+	close(a.events)
+	<-a.done
+}
+
+// EventRecorderObserver is an Observer that emits apiv1.Event objects on the
+// affected Node, using reason strings drawn from the same taxonomy as
+// Reason, so cluster operators can see scale-down lifecycle transitions via
+// `kubectl describe node` or an audit pipeline without touching metrics.
+type EventRecorderObserver struct {
+	recorder record.EventRecorder
+}
+
+// NewEventRecorderObserver creates an EventRecorderObserver that emits
+// events through recorder.
+func NewEventRecorderObserver(recorder record.EventRecorder) *EventRecorderObserver {
+	return &EventRecorderObserver{recorder: recorder}
+}
+
+func nodeRef(nodeName string) *apiv1.ObjectReference {
+	return &apiv1.ObjectReference{
+		Kind: "Node",
+		Name: nodeName,
+	}
+}
+
+// OnUnneeded implements Observer.
+func (e *EventRecorderObserver) OnUnneeded(nodeName string, _ time.Time, reason Reason) {
+	e.recorder.Eventf(nodeRef(nodeName), apiv1.EventTypeNormal, reason.String(), "Node marked unneeded for scale-down (reason: %s)", reason)
+}
+
+// OnUnneededCleared implements Observer.
+func (e *EventRecorderObserver) OnUnneededCleared(nodeName string, duration time.Duration) {
+	e.recorder.Eventf(nodeRef(nodeName), apiv1.EventTypeNormal, "UnneededCleared", "Node no longer unneeded after %s", duration)
+}
+
+// OnDeletionObserved implements Observer.
+func (e *EventRecorderObserver) OnDeletionObserved(nodeName string, duration, threshold time.Duration) {
+	e.recorder.Eventf(nodeRef(nodeName), apiv1.EventTypeNormal, "ScaleDownDeletion", "Node removed by scale-down after being unneeded for %s (threshold %s)", duration, threshold)
+}
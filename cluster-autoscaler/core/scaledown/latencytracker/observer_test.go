@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latencytracker
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type fakeObserver struct {
+	unneeded         []string
+	cleared          []string
+	deletionObserved []string
+}
+
+func (f *fakeObserver) OnUnneeded(nodeName string, _ time.Time, _ Reason) {
+	f.unneeded = append(f.unneeded, nodeName)
+}
+
+func (f *fakeObserver) OnUnneededCleared(nodeName string, _ time.Duration) {
+	f.cleared = append(f.cleared, nodeName)
+}
+
+func (f *fakeObserver) OnDeletionObserved(nodeName string, _ time.Duration, _ time.Duration) {
+	f.deletionObserved = append(f.deletionObserved, nodeName)
+}
+
+func TestNodeLatencyTracker_ObserverFanOut(t *testing.T) {
+	tracker := NewNodeLatencyTracker()
+	observer := &fakeObserver{}
+	tracker.AddObserver(observer)
+
+	now := time.Now()
+	node := []*apiv1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}}
+	tracker.UpdateStateWithUnneededList(node, map[string]bool{}, now, ReasonEmpty)
+	if len(observer.unneeded) != 1 || observer.unneeded[0] != "node1" {
+		t.Fatalf("expected OnUnneeded to fire for node1, got %+v", observer.unneeded)
+	}
+
+	tracker.ObserveDeletion("node1", now.Add(time.Minute))
+	if len(observer.deletionObserved) != 1 || observer.deletionObserved[0] != "node1" {
+		t.Fatalf("expected OnDeletionObserved to fire for node1, got %+v", observer.deletionObserved)
+	}
+
+	tracker.UpdateStateWithUnneededList(node, map[string]bool{}, now, ReasonEmpty)
+	tracker.UpdateStateWithUnneededList(nil, map[string]bool{}, now.Add(time.Minute), ReasonEmpty)
+	if len(observer.cleared) != 1 || observer.cleared[0] != "node1" {
+		t.Fatalf("expected OnUnneededCleared to fire for node1, got %+v", observer.cleared)
+	}
+}
+
+func TestAsyncObserver(t *testing.T) {
+	observer := &fakeObserver{}
+	async := NewAsyncObserver(observer, 10)
+
+	async.OnUnneeded("node1", time.Now(), ReasonEmpty)
+	async.Close()
+
+	if len(observer.unneeded) != 1 || observer.unneeded[0] != "node1" {
+		t.Fatalf("expected the wrapped observer to receive the event after Close, got %+v", observer.unneeded)
+	}
+}
+
+func TestNodeLatencyTracker_CloseFlushesAsyncObservers(t *testing.T) {
+	tracker := NewNodeLatencyTracker()
+	observer := &fakeObserver{}
+	tracker.AddObserver(NewAsyncObserver(observer, 10))
+
+	tracker.UpdateStateWithUnneededList([]*apiv1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node1"}},
+	}, map[string]bool{}, time.Now(), ReasonEmpty)
+
+	tracker.Close()
+
+	if len(observer.unneeded) != 1 || observer.unneeded[0] != "node1" {
+		t.Fatalf("expected Close to flush the queued event to the wrapped observer, got %+v", observer.unneeded)
+	}
+}
+
+// reentrantObserver calls back into the tracker from its callbacks, the way
+// an Observer that also reports on tracker state (e.g. via GetTrackedNodes)
+// would. Notifications must fire after the tracker's lock is released, or
+// this deadlocks.
+type reentrantObserver struct {
+	tracker *NodeLatencyTracker
+}
+
+func (r *reentrantObserver) OnUnneeded(string, time.Time, Reason) {
+	r.tracker.GetTrackedNodes()
+}
+
+func (r *reentrantObserver) OnUnneededCleared(string, time.Duration) {
+	r.tracker.GetTrackedNodes()
+}
+
+func (r *reentrantObserver) OnDeletionObserved(string, time.Duration, time.Duration) {
+	r.tracker.GetTrackedNodes()
+}
+
+func TestNodeLatencyTracker_ObserverCanCallBackIntoTrackerWithoutDeadlock(t *testing.T) {
+	tracker := NewNodeLatencyTracker()
+	tracker.AddObserver(&reentrantObserver{tracker: tracker})
+
+	baseTime := time.Now()
+	node := []*apiv1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}}
+
+	tracker.UpdateStateWithUnneededList(node, map[string]bool{}, baseTime, ReasonEmpty)
+	tracker.UpdateStateWithUnneededList(nil, map[string]bool{}, baseTime.Add(time.Second), ReasonEmpty)
+
+	tracker.UpdateStateWithUnneededList(node, map[string]bool{}, baseTime, ReasonEmpty)
+	tracker.ObserveDeletion("node1", baseTime.Add(time.Second))
+}
+
+func TestEventRecorderObserver(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	observer := NewEventRecorderObserver(recorder)
+
+	observer.OnUnneeded("node1", time.Now(), ReasonDrifted)
+
+	select {
+	case event := <-recorder.Events:
+		if event == "" {
+			t.Fatal("expected a non-empty event")
+		}
+	default:
+		t.Fatal("expected an event to be recorded")
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latencytracker
+
+import "sync"
+
+// StateStore persists per-node LatencyTracker state so that an autoscaler
+// restart or a leader-election handover does not lose track of how long a
+// node has been unneeded.
+type StateStore interface {
+	// Load returns the full set of persisted node states, keyed by node name.
+	Load() (map[string]NodeInfo, error)
+	// Save persists (or overwrites) the state for a single node.
+	Save(nodeName string, info NodeInfo) error
+	// Delete removes any persisted state for a single node.
+	Delete(nodeName string) error
+	// SaveBatch applies updates (upserts) and deletes in a single operation.
+	// Implementations backed by a remote API should use this to fold a whole
+	// reconcile's worth of node changes into one round trip, instead of one
+	// per node.
+	SaveBatch(updates map[string]NodeInfo, deletes []string) error
+}
+
+// InMemoryStateStore is a StateStore backed by a process-local map. It
+// preserves the tracker's historical behavior of not surviving restarts,
+// and is the default store used by NewNodeLatencyTracker.
+type InMemoryStateStore struct {
+	mu    sync.Mutex
+	nodes map[string]NodeInfo
+}
+
+// NewInMemoryStateStore creates an empty in-memory StateStore.
+func NewInMemoryStateStore() *InMemoryStateStore {
+	return &InMemoryStateStore{
+		nodes: make(map[string]NodeInfo),
+	}
+}
+
+// Load returns a copy of all currently stored node states.
+func (s *InMemoryStateStore) Load() (map[string]NodeInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]NodeInfo, len(s.nodes))
+	for name, info := range s.nodes {
+		out[name] = info
+	}
+	return out, nil
+}
+
+// Save stores the state for a single node.
+func (s *InMemoryStateStore) Save(nodeName string, info NodeInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nodes[nodeName] = info
+	return nil
+}
+
+// Delete removes the state for a single node.
+func (s *InMemoryStateStore) Delete(nodeName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.nodes, nodeName)
+	return nil
+}
+
+// SaveBatch applies updates and deletes to the in-memory map under a single
+// lock acquisition.
+func (s *InMemoryStateStore) SaveBatch(updates map[string]NodeInfo, deletes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, info := range updates {
+		s.nodes[name] = info
+	}
+	for _, name := range deletes {
+		delete(s.nodes, name)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latencytracker
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInMemoryStateStore(t *testing.T) {
+	store := NewInMemoryStateStore()
+	baseTime := time.Now()
+
+	if err := store.Save("node1", NodeInfo{UnneededSince: baseTime, Threshold: time.Second, Reason: ReasonEmpty}); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(loaded) != 1 || loaded["node1"].Reason != ReasonEmpty {
+		t.Fatalf("expected node1 with reason %q, got %+v", ReasonEmpty, loaded)
+	}
+
+	if err := store.Delete("node1"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	loaded, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(loaded) != 0 {
+		t.Fatalf("expected no nodes after delete, got %+v", loaded)
+	}
+}
+
+func TestInMemoryStateStoreSaveBatch(t *testing.T) {
+	store := NewInMemoryStateStore()
+	baseTime := time.Now()
+	if err := store.Save("node1", NodeInfo{UnneededSince: baseTime, Reason: ReasonEmpty}); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	err := store.SaveBatch(
+		map[string]NodeInfo{"node2": {UnneededSince: baseTime, Reason: ReasonDrifted}},
+		[]string{"node1"},
+	)
+	if err != nil {
+		t.Fatalf("SaveBatch returned error: %v", err)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if _, ok := loaded["node1"]; ok {
+		t.Fatalf("expected node1 to have been deleted by SaveBatch, got %+v", loaded)
+	}
+	if _, ok := loaded["node2"]; !ok {
+		t.Fatalf("expected node2 to have been saved by SaveBatch, got %+v", loaded)
+	}
+}
+
+func TestNewNodeLatencyTrackerFromStore(t *testing.T) {
+	baseTime := time.Now()
+	store := NewInMemoryStateStore()
+	if err := store.Save("node1", NodeInfo{UnneededSince: baseTime, Threshold: 2 * time.Second, Reason: ReasonDrifted}); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	tracker, err := NewNodeLatencyTrackerFromStore(store)
+	if err != nil {
+		t.Fatalf("NewNodeLatencyTrackerFromStore returned error: %v", err)
+	}
+
+	tracked := tracker.GetTrackedNodes()
+	if len(tracked) != 1 || tracked[0] != "node1" {
+		t.Fatalf("expected tracker to rehydrate node1, got %v", tracked)
+	}
+	if got := tracker.nodes["node1"].Reason; got != ReasonDrifted {
+		t.Errorf("expected rehydrated reason %q, got %q", ReasonDrifted, got)
+	}
+}
+
+func TestNodeLatencyTrackerWriteThrough(t *testing.T) {
+	store := NewInMemoryStateStore()
+	tracker, err := NewNodeLatencyTrackerFromStore(store)
+	if err != nil {
+		t.Fatalf("NewNodeLatencyTrackerFromStore returned error: %v", err)
+	}
+
+	baseTime := time.Now()
+	node := []*apiv1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}}
+	tracker.UpdateStateWithUnneededList(node, map[string]bool{}, baseTime, ReasonEmpty)
+
+	persisted, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if _, ok := persisted["node1"]; !ok {
+		t.Fatalf("expected node1 to be persisted after UpdateStateWithUnneededList, got %+v", persisted)
+	}
+
+	tracker.ObserveDeletion("node1", baseTime.Add(time.Second))
+	persisted, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if _, ok := persisted["node1"]; ok {
+		t.Fatalf("expected node1 to be removed from the store after ObserveDeletion, got %+v", persisted)
+	}
+}
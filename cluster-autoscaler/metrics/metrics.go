@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics exposes the Prometheus metrics emitted by the cluster
+// autoscaler.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const caNamespace = "cluster_autoscaler"
+
+var scaleDownNodeRemovalLatency = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: caNamespace,
+		Name:      "scale_down_node_removal_latency_seconds",
+		Help: "Time between a node being marked unneeded and it being removed, adjusted for its configured " +
+			"threshold, labeled by the reason it was marked unneeded and by whether the removal was directly " +
+			"observed by the actuator.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	},
+	[]string{"reason", "observed"},
+)
+
+var scaleDownUnneededDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: caNamespace,
+		Name:      "scale_down_unneeded_duration_seconds",
+		Help: "Distribution of how long nodes spend unneeded past their scale-down threshold, labeled by the " +
+			"reason they were marked unneeded.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	},
+	[]string{"reason"},
+)
+
+var scaleDownStuckUnneededTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: caNamespace,
+		Name:      "scale_down_stuck_unneeded_total",
+		Help: "Number of times a node was found to have been unneeded for far longer than its scale-down " +
+			"threshold without progressing to deletion, labeled by the reason it was marked unneeded.",
+	},
+	[]string{"reason"},
+)
+
+func init() {
+	prometheus.MustRegister(scaleDownNodeRemovalLatency)
+	prometheus.MustRegister(scaleDownUnneededDuration)
+	prometheus.MustRegister(scaleDownStuckUnneededTotal)
+}
+
+// UpdateScaleDownNodeRemovalLatency records how long, past its configured
+// threshold, a node took to go from being marked unneeded to being removed,
+// labeled by reason and by whether the removal was directly observed by the
+// actuator (true) or inferred because the node disappeared from the
+// unneeded list without going through ObserveDeletion (false).
+func UpdateScaleDownNodeRemovalLatency(reason string, observed bool, duration time.Duration) {
+	scaleDownNodeRemovalLatency.WithLabelValues(reason, strconv.FormatBool(observed)).Observe(duration.Seconds())
+}
+
+// UpdateScaleDownUnneededDuration records, for reason, how long a node spent
+// unneeded past its configured scale-down threshold.
+func UpdateScaleDownUnneededDuration(reason string, duration time.Duration) {
+	scaleDownUnneededDuration.WithLabelValues(reason).Observe(duration.Seconds())
+}
+
+// RegisterScaleDownStuckUnneeded increments the counter of nodes found to
+// have been unneeded for far longer than their scale-down threshold without
+// progressing to deletion, for reason.
+func RegisterScaleDownStuckUnneeded(reason string) {
+	scaleDownStuckUnneededTotal.WithLabelValues(reason).Inc()
+}